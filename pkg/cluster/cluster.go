@@ -1,22 +1,21 @@
 package cluster
 
 import (
-	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"math"
 	"os"
 	"regexp"
-	"strconv"
 	"strings"
 	"time"
 
-	"github.com/docker/docker/api/types"
 	"github.com/ghodss/yaml"
 	"github.com/mitchellh/go-homedir"
 	log "github.com/sirupsen/logrus"
 	"github.com/weaveworks/footloose/pkg/config"
-	"sigs.k8s.io/kind/pkg/docker"
+	"github.com/weaveworks/footloose/pkg/registry"
+	"github.com/weaveworks/footloose/pkg/runtime"
 	"sigs.k8s.io/kind/pkg/exec"
 )
 
@@ -28,22 +27,30 @@ type Container struct {
 // Cluster is a running cluster.
 type Cluster struct {
 	spec config.Config
+	rt   runtime.Runtime
 }
 
 // New creates a new cluster. It takes as input the description of the cluster
 // and its machines.
-func New(conf config.Config) *Cluster {
+func New(conf config.Config) (*Cluster, error) {
+	rt, err := runtime.New(conf.Cluster.Runtime)
+	if err != nil {
+		return nil, err
+	}
 	return &Cluster{
 		spec: conf,
-	}
+		rt:   rt,
+	}, nil
 }
 
 // NewFromYAML creates a new Cluster from a YAML serialization of its
 // configuration available in the provided string.
 func NewFromYAML(data []byte) (*Cluster, error) {
 	spec := config.Config{}
-	err := yaml.Unmarshal(data, &spec)
-	return New(spec), err
+	if err := yaml.Unmarshal(data, &spec); err != nil {
+		return nil, err
+	}
+	return New(spec)
 }
 
 // NewFromFile creates a new Cluster from a YAML serialization of its
@@ -79,6 +86,7 @@ func (c *Cluster) machine(spec *config.Machine, i int) *Machine {
 		spec:     spec,
 		name:     c.containerName(spec, i),
 		hostname: f(spec.Name, i),
+		rt:       c.rt,
 	}
 
 }
@@ -127,7 +135,6 @@ func (c *Cluster) publicKey() ([]byte, error) {
 
 func (c *Cluster) createMachine(machine *Machine, i int) error {
 	name := machine.ContainerName()
-	runArgs := c.createMachineRunArgs(machine, name, i)
 
 	// Start the container.
 	log.Infof("Creating machine: %s ...", name)
@@ -137,79 +144,81 @@ func (c *Cluster) createMachine(machine *Machine, i int) error {
 		return nil
 	}
 
-	cmd := "/sbin/init"
-	if machine.spec.Cmd != "" {
-		cmd = machine.spec.Cmd
-	}
-
-	_, err := docker.Run(machine.spec.Image,
-		runArgs,
-		[]string{cmd},
-	)
+	spec, err := c.runSpec(machine, name, i)
 	if err != nil {
 		return err
 	}
+	if err := c.rt.Run(spec); err != nil {
+		return err
+	}
 
 	// Initial provisioning.
-	if err := containerRunShell(name, initScript); err != nil {
+	if err := c.containerRunShell(name, initScript); err != nil {
 		return err
 	}
 	publicKey, err := c.publicKey()
 	if err != nil {
 		return err
 	}
-	if err := copy(name, publicKey, "/root/.ssh/authorized_keys"); err != nil {
+	if err := c.copyContent(name, publicKey, "/root/.ssh/authorized_keys"); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (c *Cluster) createMachineRunArgs(machine *Machine, name string, i int) []string {
-	runArgs := []string{
-		"-it", "-d",
-		"--label", "works.weave.owner=footloose",
-		"--label", "works.weave.cluster=" + c.spec.Cluster.Name,
-		"--name", name,
-		"--hostname", machine.Hostname(),
-		"--tmpfs", "/run",
-		"--tmpfs", "/run/lock",
-		"--tmpfs", "/tmp",
-		"-v", "/sys/fs/cgroup:/sys/fs/cgroup:ro",
+// runSpec builds the backend-neutral description of machine's container.
+// Mapping it to a specific engine's flags is the Runtime implementation's
+// job, not the cluster package's.
+func (c *Cluster) runSpec(machine *Machine, name string, i int) (runtime.RunSpec, error) {
+	cmd := "/sbin/init"
+	if machine.spec.Cmd != "" {
+		cmd = machine.spec.Cmd
+	}
+
+	spec := runtime.RunSpec{
+		Image:    machine.spec.Image,
+		Name:     name,
+		Hostname: machine.Hostname(),
+		Cmd:      []string{cmd},
+		Labels: map[string]string{
+			"works.weave.owner":   "footloose",
+			"works.weave.cluster": c.spec.Cluster.Name,
+		},
+		Privileged:       machine.spec.Privileged,
+		ReadOnly:         machine.spec.ReadOnly,
+		PidNamespace:     machine.spec.PidNamespace,
+		IpcNamespace:     machine.spec.IpcNamespace,
+		NetworkNamespace: machine.spec.NetworkNamespace,
 	}
 
 	for _, volume := range machine.spec.Volumes {
-		mount := f("type=%s", volume.Type)
-		if volume.Source != "" {
-			mount += f(",src=%s", volume.Source)
-		}
-		mount += f(",dst=%s", volume.Destination)
-		if volume.ReadOnly {
-			mount += ",readonly"
-		}
-		runArgs = append(runArgs, "--mount", mount)
+		spec.Mounts = append(spec.Mounts, runtime.RunMount{
+			Type:           volume.Type,
+			Source:         volume.Source,
+			Destination:    volume.Destination,
+			ReadOnly:       volume.ReadOnly,
+			SELinuxRelabel: volume.SELinuxRelabel,
+		})
 	}
 
 	for _, mapping := range machine.spec.PortMappings {
-		publish := ""
-		if mapping.Address != "" {
-			publish += f("%s:", mapping.Address)
-		}
+		var hostPort int
 		if mapping.HostPort != 0 {
-			publish += f("%d:", int(mapping.HostPort)+i)
-		}
-		publish += f("%d", mapping.ContainerPort)
-		if mapping.Protocol != "" {
-			publish += f("/%s", mapping.Protocol)
+			hostPort = int(mapping.HostPort) + i
+			if hostPort > math.MaxUint16 {
+				return runtime.RunSpec{}, fmt.Errorf("machine %s: host port %d is out of range", name, hostPort)
+			}
 		}
-		runArgs = append(runArgs, "-p", publish)
+		spec.Ports = append(spec.Ports, runtime.RunPort{
+			Address:       mapping.Address,
+			HostPort:      uint16(hostPort),
+			ContainerPort: mapping.ContainerPort,
+			Protocol:      mapping.Protocol,
+		})
 	}
 
-	if machine.spec.Privileged {
-		runArgs = append(runArgs, "--privileged")
-	}
-
-	return runArgs
+	return spec, nil
 }
 
 // Create creates the cluster.
@@ -217,8 +226,9 @@ func (c *Cluster) Create() error {
 	if err := c.ensureSSHKey(); err != nil {
 		return err
 	}
+	puller := registry.NewPuller(c.rt, c.spec.Cluster.Registry)
 	for _, template := range c.spec.Machines {
-		if _, err := docker.PullIfNotPresent(template.Spec.Image, 2); err != nil {
+		if err := puller.Pull(template.Spec.Image); err != nil {
 			return err
 		}
 	}
@@ -234,22 +244,13 @@ func (c *Cluster) deleteMachine(machine *Machine, i int) error {
 
 	if machine.IsStarted() {
 		log.Infof("Machine with name %s is started, stopping and deleting machine...", name)
-		err := docker.Kill("KILL", name)
-		if err != nil {
+		if err := c.rt.Kill(name, "KILL"); err != nil {
 			return err
 		}
-		cmd := exec.Command(
-			"docker", "rm",
-			name,
-		)
-		return cmd.Run()
+		return c.rt.Rm(name)
 	}
 	log.Infof("Deleting machine: %s ...", name)
-	cmd := exec.Command(
-		"docker", "rm",
-		name,
-	)
-	return cmd.Run()
+	return c.rt.Rm(name)
 }
 
 // Delete deletes the cluster.
@@ -294,56 +295,38 @@ func (c *Cluster) gatherMachines() (machines []*Machine, err error) {
 	machines = c.gatherMachinesByCluster()
 	for _, m := range machines {
 		if m.IsRunning() {
-			inspect, err := c.gatherMachineDetails(m.name)
+			details, err := c.rt.Inspect(m.name)
 			if err != nil {
 				return machines, err
 			}
 			// Set Ports
 			ports := make([]config.PortMapping, 0)
-			for k, v := range inspect.NetworkSettings.Ports {
-				if len(v) < 1 {
-					continue
-				}
-				p := config.PortMapping{}
-				hostPort, _ := strconv.Atoi(v[0].HostPort)
-				p.HostPort = uint16(k.Int())
-				p.ContainerPort = uint16(hostPort)
-				p.Address = v[0].HostIP
-				ports = append(ports, p)
+			for _, p := range details.Ports {
+				ports = append(ports, config.PortMapping{
+					HostPort:      p.HostPort,
+					ContainerPort: p.ContainerPort,
+					Address:       p.HostIP,
+				})
 			}
 			m.spec.PortMappings = ports
 			// Volumes
 			var volumes []config.Volume
-			for _, mount := range inspect.Mounts {
-				v := config.Volume{
-					Type:        string(mount.Type),
+			for _, mount := range details.Mounts {
+				volumes = append(volumes, config.Volume{
+					Type:        mount.Type,
 					Source:      mount.Source,
 					Destination: mount.Destination,
-					ReadOnly:    mount.RW,
-				}
-				volumes = append(volumes, v)
+					ReadOnly:    mount.ReadOnly,
+				})
 			}
 			m.spec.Volumes = volumes
-			m.spec.Cmd = strings.Join(inspect.Config.Cmd, ",")
-			m.ip = inspect.NetworkSettings.IPAddress
+			m.spec.Cmd = strings.Join(details.Cmd, ",")
+			m.ip = details.IPAddress
 		}
 	}
 	return
 }
 
-func (c *Cluster) gatherMachineDetails(name string) (container types.ContainerJSON, err error) {
-	res, err := docker.Inspect(name, "{{json .}}")
-	if err != nil {
-		return container, err
-	}
-	data := []byte(strings.Trim(res[0], "'"))
-	err = json.Unmarshal(data, &container)
-	if err != nil {
-		return container, err
-	}
-	return
-}
-
 func (c *Cluster) gatherMachinesByCluster() (machines []*Machine) {
 	for _, template := range c.spec.Machines {
 		for i := 0; i < template.Count; i++ {
@@ -366,14 +349,7 @@ func (c *Cluster) startMachine(machine *Machine, i int) error {
 		return nil
 	}
 	log.Infof("Starting machine: %s ...", name)
-
-	// Run command while sigs.k8s.io/kind/pkg/container/docker doesn't
-	// have a start command
-	cmd := exec.Command(
-		"docker", "start",
-		name,
-	)
-	return cmd.Run()
+	return c.rt.Start(name)
 }
 
 // Start starts the machines in cluster.
@@ -393,14 +369,7 @@ func (c *Cluster) stopMachine(machine *Machine, i int) error {
 		return nil
 	}
 	log.Infof("Stopping machine: %s ...", name)
-
-	// Run command while sigs.k8s.io/kind/pkg/container/docker doesn't
-	// have a start command
-	cmd := exec.Command(
-		"docker", "stop",
-		name,
-	)
-	return cmd.Run()
+	return c.rt.Stop(name)
 }
 
 // Stop stops the machines in cluster.
@@ -0,0 +1,210 @@
+package cluster
+
+import (
+	"archive/tar"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// Ownership overrides the uid/gid recorded for copied files, in place of
+// whatever ownership the local filesystem reports.
+type Ownership struct {
+	UID int
+	GID int
+}
+
+// CopyOptions controls how Machine.CopyTo transfers a local file tree.
+type CopyOptions struct {
+	// Chown overrides the ownership of every copied entry. A nil Chown
+	// preserves each local file's own ownership.
+	Chown *Ownership
+
+	// FollowSymlinks copies the file or directory a symlink points at,
+	// instead of the symlink itself.
+	FollowSymlinks bool
+
+	// Excludes lists glob patterns, matched against paths relative to
+	// localPath, of entries to skip. Inside a directory reached by
+	// following a symlink, they are matched against paths relative to the
+	// symlink's target instead.
+	Excludes []string
+}
+
+// CopyTo copies localPath, a file or a directory tree, into the machine at
+// remotePath, by streaming a tar archive through the runtime's Copy.
+func (m *Machine) CopyTo(localPath, remotePath string, opts CopyOptions) error {
+	pr, pw := io.Pipe()
+
+	go func() {
+		tw := tar.NewWriter(pw)
+
+		base := filepath.Base(localPath)
+		err := filepath.Walk(localPath, func(p string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			rel, err := filepath.Rel(localPath, p)
+			if err != nil {
+				return err
+			}
+			if excluded(rel, opts.Excludes) {
+				if info.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+
+			name := base
+			if rel != "." {
+				name = path.Join(base, filepath.ToSlash(rel))
+			}
+			return addTarEntry(tw, p, name, info, opts)
+		})
+		if err == nil {
+			err = tw.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+
+	return m.rt.Copy(m.name, pr, remotePath)
+}
+
+// CopyFrom copies remotePath, a file or a directory tree, out of the
+// machine to localPath, by reading a tar archive through the runtime's
+// CopyFrom.
+func (m *Machine) CopyFrom(remotePath, localPath string) error {
+	tarStream, err := m.rt.CopyFrom(m.name, remotePath)
+	if err != nil {
+		return err
+	}
+
+	tr := tar.NewReader(tarStream)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		dest := filepath.Join(localPath, filepath.FromSlash(hdr.Name))
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(dest, os.FileMode(hdr.Mode)); err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			if err := os.Symlink(hdr.Linkname, dest); err != nil {
+				return err
+			}
+		default:
+			if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+				return err
+			}
+			if err := writeTarFile(dest, tr, os.FileMode(hdr.Mode)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func writeTarFile(dest string, r io.Reader, mode os.FileMode) error {
+	f, err := os.OpenFile(dest, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(f, r)
+	return err
+}
+
+func excluded(rel string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := filepath.Match(pattern, rel); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// addSymlinkedDir walks target, the resolved directory a symlink points at,
+// adding its contents under name. It is used in place of the single tar
+// entry filepath.Walk would otherwise produce for the symlink itself, since
+// Walk never descends into a symlink's target.
+func addSymlinkedDir(tw *tar.Writer, target, name string, opts CopyOptions) error {
+	return filepath.Walk(target, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(target, p)
+		if err != nil {
+			return err
+		}
+		if excluded(rel, opts.Excludes) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		entryName := name
+		if rel != "." {
+			entryName = path.Join(name, filepath.ToSlash(rel))
+		}
+		return addTarEntry(tw, p, entryName, info, opts)
+	})
+}
+
+func addTarEntry(tw *tar.Writer, localPath, name string, info os.FileInfo, opts CopyOptions) error {
+	link := ""
+	if info.Mode()&os.ModeSymlink != 0 {
+		if opts.FollowSymlinks {
+			target, err := filepath.EvalSymlinks(localPath)
+			if err != nil {
+				return err
+			}
+			targetInfo, err := os.Stat(target)
+			if err != nil {
+				return err
+			}
+			if targetInfo.IsDir() {
+				return addSymlinkedDir(tw, target, name, opts)
+			}
+			localPath, info = target, targetInfo
+		} else {
+			l, err := os.Readlink(localPath)
+			if err != nil {
+				return err
+			}
+			link = l
+		}
+	}
+
+	hdr, err := tar.FileInfoHeader(info, link)
+	if err != nil {
+		return err
+	}
+	hdr.Name = name
+	if opts.Chown != nil {
+		hdr.Uid = opts.Chown.UID
+		hdr.Gid = opts.Chown.GID
+	}
+
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	if !info.IsDir() && info.Mode().IsRegular() {
+		f, err := os.Open(localPath)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if _, err := io.Copy(tw, f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
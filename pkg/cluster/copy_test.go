@@ -0,0 +1,230 @@
+package cluster
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/weaveworks/footloose/pkg/runtime"
+)
+
+// fakeCopyRuntime is a minimal runtime.Runtime that stores whatever tar
+// stream Machine.CopyTo/CopyFrom pass through Copy/CopyFrom, so tests can
+// exercise the tar-building/extracting logic without a container engine.
+type fakeCopyRuntime struct {
+	runtime.Runtime
+
+	stored bytes.Buffer
+}
+
+func (f *fakeCopyRuntime) Copy(container string, tarStream io.Reader, path string) error {
+	_, err := io.Copy(&f.stored, tarStream)
+	return err
+}
+
+func (f *fakeCopyRuntime) CopyFrom(container, path string) (io.Reader, error) {
+	return bytes.NewReader(f.stored.Bytes()), nil
+}
+
+func tarEntries(t *testing.T, data []byte) map[string]*tar.Header {
+	t.Helper()
+	entries := map[string]*tar.Header{}
+	tr := tar.NewReader(bytes.NewReader(data))
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("reading tar: %v", err)
+		}
+		entries[hdr.Name] = hdr
+	}
+	return entries
+}
+
+func TestMachineCopyToBuildsTarArchive(t *testing.T) {
+	dir, err := ioutil.TempDir("", "footloose-copy-to")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	src := filepath.Join(dir, "src")
+	if err := os.Mkdir(src, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(src, "a.txt"), []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(src, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(src, "sub", "b.txt"), []byte("world"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	rt := &fakeCopyRuntime{}
+	m := &Machine{rt: rt, name: "test"}
+	if err := m.CopyTo(src, "/dest", CopyOptions{}); err != nil {
+		t.Fatalf("CopyTo() error = %v", err)
+	}
+
+	entries := tarEntries(t, rt.stored.Bytes())
+	for _, name := range []string{"src", "src/a.txt", "src/sub", "src/sub/b.txt"} {
+		if _, ok := entries[name]; !ok {
+			t.Errorf("tar is missing entry %q, got %v", name, entries)
+		}
+	}
+}
+
+func TestMachineCopyToExcludes(t *testing.T) {
+	dir, err := ioutil.TempDir("", "footloose-copy-excl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	src := filepath.Join(dir, "src")
+	if err := os.Mkdir(src, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(src, "keep.txt"), []byte("a"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(src, "skip.log"), []byte("b"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	rt := &fakeCopyRuntime{}
+	m := &Machine{rt: rt, name: "test"}
+	if err := m.CopyTo(src, "/dest", CopyOptions{Excludes: []string{"*.log"}}); err != nil {
+		t.Fatalf("CopyTo() error = %v", err)
+	}
+
+	entries := tarEntries(t, rt.stored.Bytes())
+	if _, ok := entries["src/skip.log"]; ok {
+		t.Error("excluded file was copied into the tar")
+	}
+	if _, ok := entries["src/keep.txt"]; !ok {
+		t.Error("non-excluded file is missing from the tar")
+	}
+}
+
+func TestMachineCopyToSymlinks(t *testing.T) {
+	dir, err := ioutil.TempDir("", "footloose-copy-symlink")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	src := filepath.Join(dir, "src")
+	if err := os.Mkdir(src, 0755); err != nil {
+		t.Fatal(err)
+	}
+	target := filepath.Join(src, "target.txt")
+	if err := ioutil.WriteFile(target, []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(target, filepath.Join(src, "link.txt")); err != nil {
+		t.Fatal(err)
+	}
+
+	targetDir := filepath.Join(src, "targetdir")
+	if err := os.Mkdir(targetDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(targetDir, "nested.txt"), []byte("world"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(targetDir, filepath.Join(src, "linkdir")); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("link preserved by default", func(t *testing.T) {
+		rt := &fakeCopyRuntime{}
+		m := &Machine{rt: rt, name: "test"}
+		if err := m.CopyTo(src, "/dest", CopyOptions{}); err != nil {
+			t.Fatalf("CopyTo() error = %v", err)
+		}
+		hdr := tarEntries(t, rt.stored.Bytes())["src/link.txt"]
+		if hdr == nil || hdr.Typeflag != tar.TypeSymlink {
+			t.Fatalf("expected src/link.txt to be a symlink entry, got %+v", hdr)
+		}
+	})
+
+	t.Run("link followed when requested", func(t *testing.T) {
+		rt := &fakeCopyRuntime{}
+		m := &Machine{rt: rt, name: "test"}
+		if err := m.CopyTo(src, "/dest", CopyOptions{FollowSymlinks: true}); err != nil {
+			t.Fatalf("CopyTo() error = %v", err)
+		}
+		hdr := tarEntries(t, rt.stored.Bytes())["src/link.txt"]
+		if hdr == nil || hdr.Typeflag == tar.TypeSymlink {
+			t.Fatalf("expected src/link.txt to be a regular file entry, got %+v", hdr)
+		}
+	})
+
+	t.Run("directory link followed when requested", func(t *testing.T) {
+		rt := &fakeCopyRuntime{}
+		m := &Machine{rt: rt, name: "test"}
+		if err := m.CopyTo(src, "/dest", CopyOptions{FollowSymlinks: true}); err != nil {
+			t.Fatalf("CopyTo() error = %v", err)
+		}
+		entries := tarEntries(t, rt.stored.Bytes())
+		dirHdr := entries["src/linkdir"]
+		if dirHdr == nil || dirHdr.Typeflag != tar.TypeDir {
+			t.Fatalf("expected src/linkdir to be a directory entry, got %+v", dirHdr)
+		}
+		if _, ok := entries["src/linkdir/nested.txt"]; !ok {
+			t.Fatalf("expected src/linkdir/nested.txt to be copied, got %v", entries)
+		}
+	})
+}
+
+func TestMachineCopyFromExtractsTar(t *testing.T) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	mustWriteTar(t, tw, &tar.Header{Name: "dir", Typeflag: tar.TypeDir, Mode: 0755}, nil)
+	mustWriteTar(t, tw, &tar.Header{Name: "dir/file.txt", Typeflag: tar.TypeReg, Mode: 0644, Size: 5}, []byte("hello"))
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	rt := &fakeCopyRuntime{stored: buf}
+	m := &Machine{rt: rt, name: "test"}
+
+	dest, err := ioutil.TempDir("", "footloose-copy-from")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dest)
+
+	if err := m.CopyFrom("/remote", dest); err != nil {
+		t.Fatalf("CopyFrom() error = %v", err)
+	}
+
+	got, err := ioutil.ReadFile(filepath.Join(dest, "dir", "file.txt"))
+	if err != nil {
+		t.Fatalf("reading extracted file: %v", err)
+	}
+	if string(got) != "hello" {
+		t.Fatalf("extracted content = %q, want %q", got, "hello")
+	}
+}
+
+func mustWriteTar(t *testing.T, tw *tar.Writer, hdr *tar.Header, content []byte) {
+	t.Helper()
+	if err := tw.WriteHeader(hdr); err != nil {
+		t.Fatal(err)
+	}
+	if content != nil {
+		if _, err := tw.Write(content); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,17 @@
+package cluster
+
+import (
+	"os"
+
+	"sigs.k8s.io/kind/pkg/exec"
+)
+
+// run executes name with args, connecting it to the current process'
+// standard streams.
+func run(name string, args ...string) error {
+	cmd := exec.Command(name, args...)
+	cmd.SetStdin(os.Stdin)
+	cmd.SetStdout(os.Stdout)
+	cmd.SetStderr(os.Stderr)
+	return cmd.Run()
+}
@@ -0,0 +1,52 @@
+package cluster
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+)
+
+// Formatter renders a set of machines for display by `footloose show` /
+// `footloose inspect`.
+type Formatter interface {
+	Format(machines []*Machine) error
+	FormatSingle(machine Machine) error
+}
+
+// getFormatter returns the Formatter registered under name.
+func getFormatter(name string) (Formatter, error) {
+	switch name {
+	case "", "table":
+		return tableFormatter{}, nil
+	case "json":
+		return jsonFormatter{}, nil
+	default:
+		return nil, fmt.Errorf("unknown output format %q", name)
+	}
+}
+
+type tableFormatter struct{}
+
+func (tableFormatter) Format(machines []*Machine) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tHOSTNAME\tIP\tRUNNING")
+	for _, m := range machines {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%t\n", m.name, m.hostname, m.ip, m.IsStarted())
+	}
+	return w.Flush()
+}
+
+func (f tableFormatter) FormatSingle(machine Machine) error {
+	return f.Format([]*Machine{&machine})
+}
+
+type jsonFormatter struct{}
+
+func (jsonFormatter) Format(machines []*Machine) error {
+	return json.NewEncoder(os.Stdout).Encode(machines)
+}
+
+func (jsonFormatter) FormatSingle(machine Machine) error {
+	return json.NewEncoder(os.Stdout).Encode(machine)
+}
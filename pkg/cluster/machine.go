@@ -0,0 +1,85 @@
+package cluster
+
+import (
+	"encoding/json"
+
+	"github.com/weaveworks/footloose/pkg/config"
+	"github.com/weaveworks/footloose/pkg/runtime"
+)
+
+// Machine is a single machine created by footloose. It is backed by a
+// container, started from the machine template in the configuration.
+type Machine struct {
+	spec *config.Machine
+	rt   runtime.Runtime
+
+	name     string
+	hostname string
+	ip       string
+}
+
+// ContainerName is the name of the container backing this machine.
+func (m *Machine) ContainerName() string {
+	return m.name
+}
+
+// Hostname is the machine hostname.
+func (m *Machine) Hostname() string {
+	return m.hostname
+}
+
+// IsRunning returns true if the container backing the machine exists,
+// whatever its state (started or stopped).
+func (m *Machine) IsRunning() bool {
+	_, err := m.rt.Inspect(m.name)
+	return err == nil
+}
+
+// IsStarted returns true if the container backing the machine is started.
+func (m *Machine) IsStarted() bool {
+	details, err := m.rt.Inspect(m.name)
+	if err != nil {
+		return false
+	}
+	return details.Running
+}
+
+// HostPort looks up the host port bound to the given container port.
+func (m *Machine) HostPort(containerPort int) (int, error) {
+	mapping, err := mappingFromPort(m.spec, containerPort)
+	if err != nil {
+		return 0, err
+	}
+	return int(mapping.HostPort), nil
+}
+
+// machineJSON is the JSON representation of a Machine, used by
+// `footloose show`/`inspect -o json`. Machine itself has no exported
+// fields, so encoding/json would otherwise serialize it as "{}".
+type machineJSON struct {
+	Name         string               `json:"name"`
+	Hostname     string               `json:"hostname"`
+	IP           string               `json:"ip,omitempty"`
+	Running      bool                 `json:"running"`
+	Started      bool                 `json:"started"`
+	Image        string               `json:"image,omitempty"`
+	Cmd          string               `json:"cmd,omitempty"`
+	PortMappings []config.PortMapping `json:"portMappings,omitempty"`
+	Volumes      []config.Volume      `json:"volumes,omitempty"`
+}
+
+// MarshalJSON renders the machine's public fields, since its own fields
+// are all unexported.
+func (m Machine) MarshalJSON() ([]byte, error) {
+	return json.Marshal(machineJSON{
+		Name:         m.name,
+		Hostname:     m.hostname,
+		IP:           m.ip,
+		Running:      m.IsRunning(),
+		Started:      m.IsStarted(),
+		Image:        m.spec.Image,
+		Cmd:          m.spec.Cmd,
+		PortMappings: m.spec.PortMappings,
+		Volumes:      m.spec.Volumes,
+	})
+}
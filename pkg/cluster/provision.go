@@ -0,0 +1,34 @@
+package cluster
+
+import (
+	"archive/tar"
+	"bytes"
+	"path"
+)
+
+// containerRunShell runs script inside container using /bin/sh.
+func (c *Cluster) containerRunShell(container, script string) error {
+	return c.rt.Exec(container, "/bin/sh", "-c", script)
+}
+
+// copyContent writes content to dst inside container, as a single file with
+// mode 0600, by streaming a minimal tar archive through the runtime's Copy.
+func (c *Cluster) copyContent(container string, content []byte, dst string) error {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	err := tw.WriteHeader(&tar.Header{
+		Name: path.Base(dst),
+		Mode: 0600,
+		Size: int64(len(content)),
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := tw.Write(content); err != nil {
+		return err
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return c.rt.Copy(container, &buf, path.Dir(dst))
+}
@@ -0,0 +1,120 @@
+package config
+
+// Config is the top level structure of a footloose configuration file.
+type Config struct {
+	Cluster  Cluster          `json:"cluster"`
+	Machines []MachineReplica `json:"machines,omitempty"`
+}
+
+// Cluster describes the cluster wide settings.
+type Cluster struct {
+	// Name of the cluster.
+	Name string `json:"name,omitempty"`
+
+	// PrivateKey is the path to the SSH private key used to connect to
+	// the machines. The public key is expected to be at the same path
+	// with a ".pub" suffix.
+	PrivateKey string `json:"privateKey,omitempty"`
+
+	// Runtime selects the container backend used to run the machines:
+	// "docker" (the default) or "podman". See pkg/runtime.
+	Runtime string `json:"runtime,omitempty"`
+
+	// Registry configures how machine images are pulled. See pkg/registry.
+	Registry Registry `json:"registry,omitempty"`
+}
+
+// Registry configures how machine images are pulled: through mirrors, over
+// plain HTTP or self-signed TLS, and/or with credentials.
+type Registry struct {
+	// Mirrors lists pull-through caches tried, in order, before an
+	// image's canonical registry.
+	Mirrors []string `json:"mirrors,omitempty"`
+
+	// InsecureRegistries lists registry hosts (mirrors or canonical)
+	// that should be contacted over plain HTTP, or over HTTPS without
+	// verifying their certificate. Only the "podman" runtime acts on it;
+	// the "docker" runtime pulls with the daemon's own registry settings.
+	InsecureRegistries []string `json:"insecureRegistries,omitempty"`
+
+	// Auth maps a registry host to the credentials used to authenticate
+	// against it before pulling.
+	Auth map[string]RegistryAuth `json:"auth,omitempty"`
+}
+
+// RegistryAuth is the credentials used to authenticate against a single
+// registry host. Username/Password are used directly; CredentialHelper
+// names a Docker credential helper that is assumed to already be
+// configured, in which case footloose performs no login of its own.
+type RegistryAuth struct {
+	Username         string `json:"username,omitempty"`
+	Password         string `json:"password,omitempty"`
+	CredentialHelper string `json:"credentialHelper,omitempty"`
+}
+
+// MachineReplica is a machine template together with the number of
+// instances of it that should be created.
+type MachineReplica struct {
+	Count int     `json:"count,omitempty"`
+	Spec  Machine `json:"spec,omitempty"`
+}
+
+// Machine describes a single machine template.
+type Machine struct {
+	// Name is go-template for the name of the machine(s), e.g. "node%d".
+	Name string `json:"name,omitempty"`
+
+	// Image is the Docker image to use for this machine.
+	Image string `json:"image,omitempty"`
+
+	// Privileged makes the machine's container run in privileged mode.
+	Privileged bool `json:"privileged,omitempty"`
+
+	// Cmd overrides the default command run by the container.
+	Cmd string `json:"cmd,omitempty"`
+
+	// ReadOnly makes the machine's root filesystem read-only for the whole
+	// lifetime of the container. /root is additionally overlaid with a
+	// tmpfs, so footloose can still write there (e.g. to install the SSH
+	// key) even though the underlying image layers are read-only.
+	ReadOnly bool `json:"readOnly,omitempty"`
+
+	// PidNamespace sets the PID namespace of the machine, e.g. "host" or
+	// "container:<name>". Defaults to the container's own namespace.
+	PidNamespace string `json:"pidNamespace,omitempty"`
+
+	// IpcNamespace sets the IPC namespace of the machine, e.g. "host" or
+	// "container:<name>". Defaults to the container's own namespace.
+	IpcNamespace string `json:"ipcNamespace,omitempty"`
+
+	// NetworkNamespace sets the network namespace of the machine, e.g.
+	// "host", "none" or "container:<name>". Defaults to the container's
+	// own namespace.
+	NetworkNamespace string `json:"networkNamespace,omitempty"`
+
+	PortMappings []PortMapping `json:"portMappings,omitempty"`
+	Volumes      []Volume      `json:"volumes,omitempty"`
+}
+
+// Volume is a volume mounted in a machine.
+type Volume struct {
+	Type        string `json:"type,omitempty"`
+	Source      string `json:"source,omitempty"`
+	Destination string `json:"destination,omitempty"`
+	ReadOnly    bool   `json:"readOnly,omitempty"`
+
+	// SELinuxRelabel requests that the bind-mount be relabelled for use
+	// in the container, on hosts with SELinux in enforcing mode. One of
+	// "" (no relabelling), "shared" (the content is shared between
+	// multiple containers, `z`) or "private" (the content is private to
+	// this container, `Z`). Only meaningful for the "bind" volume type.
+	SELinuxRelabel string `json:"seLinuxRelabel,omitempty"`
+}
+
+// PortMapping describes a port forwarded from the host to a machine.
+type PortMapping struct {
+	Address       string `json:"address,omitempty"`
+	ContainerPort uint16 `json:"containerPort,omitempty"`
+	HostPort      uint16 `json:"hostPort,omitempty"`
+	Protocol      string `json:"protocol,omitempty"`
+}
@@ -0,0 +1,100 @@
+// Package registry resolves the registries and credentials footloose uses
+// to pull machine images, so that users in air-gapped or CI environments
+// can point at a local mirror without reconfiguring the host's container
+// engine.
+package registry
+
+import (
+	"strings"
+
+	"github.com/weaveworks/footloose/pkg/config"
+	"github.com/weaveworks/footloose/pkg/runtime"
+)
+
+// host returns the registry host component of image, or "" if image uses
+// the runtime's default registry (e.g. "ubuntu" or "library/ubuntu").
+func host(image string) string {
+	parts := strings.SplitN(image, "/", 2)
+	if len(parts) == 1 {
+		return ""
+	}
+	if strings.ContainsAny(parts[0], ".:") || parts[0] == "localhost" {
+		return parts[0]
+	}
+	return ""
+}
+
+// rewriteHost returns image with its registry host replaced by mirror.
+func rewriteHost(image, mirror string) string {
+	if h := host(image); h != "" {
+		return mirror + "/" + strings.TrimPrefix(image, h+"/")
+	}
+	return mirror + "/" + image
+}
+
+// Candidates returns the image references to try, in order: each of
+// conf's mirrors first, then image's own canonical reference.
+func Candidates(conf config.Registry, image string) []string {
+	candidates := make([]string, 0, len(conf.Mirrors)+1)
+	for _, mirror := range conf.Mirrors {
+		candidates = append(candidates, rewriteHost(image, mirror))
+	}
+	return append(candidates, image)
+}
+
+func insecure(conf config.Registry, host string) bool {
+	for _, r := range conf.InsecureRegistries {
+		if r == host {
+			return true
+		}
+	}
+	return false
+}
+
+// Puller pulls images through a runtime.Runtime, trying registry mirrors
+// before falling back to an image's canonical registry.
+type Puller struct {
+	rt   runtime.Runtime
+	conf config.Registry
+}
+
+// NewPuller returns a Puller that fetches images through rt according to
+// conf.
+func NewPuller(rt runtime.Runtime, conf config.Registry) *Puller {
+	return &Puller{rt: rt, conf: conf}
+}
+
+// Pull fetches image, trying each of the Puller's configured mirrors before
+// image's canonical registry. On success, if the image was actually
+// fetched from a mirror, it is tagged under image's own canonical
+// reference so that later `docker run`/`podman run` calls against image
+// resolve it locally instead of falling through to a pull against the
+// real (possibly unreachable) registry. It returns the last error if no
+// candidate could be pulled.
+func (p *Puller) Pull(image string) error {
+	var lastErr error
+	for _, candidate := range Candidates(p.conf, image) {
+		candidateHost := host(candidate)
+
+		if auth, ok := p.conf.Auth[candidateHost]; ok && auth.CredentialHelper == "" {
+			if err := p.rt.Login(candidateHost, auth.Username, auth.Password); err != nil {
+				lastErr = err
+				continue
+			}
+		}
+
+		opts := runtime.PullOptions{Insecure: insecure(p.conf, candidateHost)}
+		if err := p.rt.Pull(candidate, opts); err != nil {
+			lastErr = err
+			continue
+		}
+
+		if candidate != image {
+			if err := p.rt.Tag(candidate, image); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return lastErr
+}
@@ -0,0 +1,141 @@
+package registry
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/weaveworks/footloose/pkg/config"
+	"github.com/weaveworks/footloose/pkg/runtime"
+)
+
+func TestCandidates(t *testing.T) {
+	tests := []struct {
+		name    string
+		mirrors []string
+		image   string
+		want    []string
+	}{
+		{
+			name:  "no mirrors falls back to canonical",
+			image: "ubuntu:18.04",
+			want:  []string{"ubuntu:18.04"},
+		},
+		{
+			name:    "mirror before canonical, unqualified image",
+			mirrors: []string{"mirror.example.com"},
+			image:   "ubuntu:18.04",
+			want:    []string{"mirror.example.com/ubuntu:18.04", "ubuntu:18.04"},
+		},
+		{
+			name:    "mirror before canonical, host-qualified image",
+			mirrors: []string{"mirror.example.com"},
+			image:   "docker.io/library/ubuntu:18.04",
+			want: []string{
+				"mirror.example.com/library/ubuntu:18.04",
+				"docker.io/library/ubuntu:18.04",
+			},
+		},
+		{
+			name:    "multiple mirrors tried in order",
+			mirrors: []string{"mirror1.example.com", "mirror2.example.com"},
+			image:   "ubuntu:18.04",
+			want: []string{
+				"mirror1.example.com/ubuntu:18.04",
+				"mirror2.example.com/ubuntu:18.04",
+				"ubuntu:18.04",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := config.Registry{Mirrors: tt.mirrors}
+			got := Candidates(conf, tt.image)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Candidates() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("Candidates() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+// fakeRuntime is a minimal runtime.Runtime that only does what Puller.Pull
+// exercises; its behaviour is driven by the test via the function fields.
+type fakeRuntime struct {
+	runtime.Runtime
+
+	pull func(image string, opts runtime.PullOptions) error
+	tag  func(source, target string) error
+}
+
+func (f *fakeRuntime) Pull(image string, opts runtime.PullOptions) error {
+	return f.pull(image, opts)
+}
+
+func (f *fakeRuntime) Login(registry, username, password string) error {
+	return nil
+}
+
+func (f *fakeRuntime) Tag(source, target string) error {
+	if f.tag == nil {
+		return nil
+	}
+	return f.tag(source, target)
+}
+
+func TestPullerPullTagsMirrorImageUnderCanonicalName(t *testing.T) {
+	var pulled, tagSource, tagTarget string
+
+	rt := &fakeRuntime{
+		pull: func(image string, opts runtime.PullOptions) error {
+			pulled = image
+			return nil
+		},
+		tag: func(source, target string) error {
+			tagSource, tagTarget = source, target
+			return nil
+		},
+	}
+
+	conf := config.Registry{Mirrors: []string{"mirror.example.com"}}
+	p := NewPuller(rt, conf)
+	if err := p.Pull("ubuntu:18.04"); err != nil {
+		t.Fatalf("Pull() error = %v", err)
+	}
+
+	if pulled != "mirror.example.com/ubuntu:18.04" {
+		t.Fatalf("pulled image = %q, want the mirror reference", pulled)
+	}
+	if tagSource != "mirror.example.com/ubuntu:18.04" || tagTarget != "ubuntu:18.04" {
+		t.Fatalf("Tag(%q, %q), want Tag(mirror, canonical)", tagSource, tagTarget)
+	}
+}
+
+func TestPullerPullFallsBackToCanonicalOnMirrorFailure(t *testing.T) {
+	var attempts []string
+
+	rt := &fakeRuntime{
+		pull: func(image string, opts runtime.PullOptions) error {
+			attempts = append(attempts, image)
+			if image == "ubuntu:18.04" {
+				return nil
+			}
+			return errors.New("mirror unreachable")
+		},
+	}
+
+	conf := config.Registry{Mirrors: []string{"mirror.example.com"}}
+	p := NewPuller(rt, conf)
+	if err := p.Pull("ubuntu:18.04"); err != nil {
+		t.Fatalf("Pull() error = %v", err)
+	}
+
+	want := []string{"mirror.example.com/ubuntu:18.04", "ubuntu:18.04"}
+	if len(attempts) != len(want) || attempts[0] != want[0] || attempts[1] != want[1] {
+		t.Fatalf("attempts = %v, want %v", attempts, want)
+	}
+}
@@ -0,0 +1,142 @@
+package runtime
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"sigs.k8s.io/kind/pkg/docker"
+	"sigs.k8s.io/kind/pkg/exec"
+)
+
+// Docker drives containers through the Docker CLI, using
+// sigs.k8s.io/kind/pkg/docker where it already does the right thing.
+type Docker struct{}
+
+// Pull fetches image if it isn't already present locally.
+//
+// opts.Insecure is ignored: the Docker CLI has no per-pull equivalent, the
+// daemon must be configured with the registry listed under
+// insecure-registries in /etc/docker/daemon.json.
+func (*Docker) Pull(image string, opts PullOptions) error {
+	_, err := docker.PullIfNotPresent(image, 2)
+	return err
+}
+
+// Login authenticates against registry so that subsequent Pull calls
+// against it succeed.
+func (*Docker) Login(registry, username, password string) error {
+	cmd := exec.Command("docker", "login", "--username", username, "--password-stdin", registry)
+	cmd.SetStdin(strings.NewReader(password))
+	return cmd.Run()
+}
+
+// Tag tags the local image source under the additional reference target.
+func (*Docker) Tag(source, target string) error {
+	return exec.Command("docker", "tag", source, target).Run()
+}
+
+// Run creates and starts a new container according to spec.
+func (*Docker) Run(spec RunSpec) error {
+	args, err := dockerCompatArgs(spec)
+	if err != nil {
+		return err
+	}
+	_, err = docker.Run(spec.Image, args, spec.Cmd)
+	return err
+}
+
+// Start (re)starts a previously stopped container.
+func (*Docker) Start(container string) error {
+	return exec.Command("docker", "start", container).Run()
+}
+
+// Stop stops a running container without removing it.
+func (*Docker) Stop(container string) error {
+	return exec.Command("docker", "stop", container).Run()
+}
+
+// Kill sends signal to the container's init process.
+func (*Docker) Kill(container, signal string) error {
+	return docker.Kill(signal, container)
+}
+
+// Rm removes a stopped container.
+func (*Docker) Rm(container string) error {
+	return exec.Command("docker", "rm", container).Run()
+}
+
+// Inspect returns details about container.
+func (*Docker) Inspect(container string) (ContainerDetails, error) {
+	var details ContainerDetails
+
+	res, err := docker.Inspect(container, "{{json .}}")
+	if err != nil {
+		return details, err
+	}
+
+	var c types.ContainerJSON
+	data := []byte(strings.Trim(res[0], "'"))
+	if err := json.Unmarshal(data, &c); err != nil {
+		return details, err
+	}
+
+	details.Running = c.State != nil && c.State.Running
+	details.IPAddress = c.NetworkSettings.IPAddress
+	details.Cmd = c.Config.Cmd
+
+	for k, bindings := range c.NetworkSettings.Ports {
+		if len(bindings) < 1 {
+			continue
+		}
+		hostPort, _ := strconv.Atoi(bindings[0].HostPort)
+		details.Ports = append(details.Ports, Port{
+			ContainerPort: uint16(k.Int()),
+			HostPort:      uint16(hostPort),
+			HostIP:        bindings[0].HostIP,
+		})
+	}
+
+	for _, mount := range c.Mounts {
+		details.Mounts = append(details.Mounts, Mount{
+			Type:        string(mount.Type),
+			Source:      mount.Source,
+			Destination: mount.Destination,
+			ReadOnly:    !mount.RW,
+		})
+	}
+
+	return details, nil
+}
+
+// Copy streams tarStream into the container, extracting it at path.
+func (*Docker) Copy(container string, tarStream io.Reader, path string) error {
+	cmd := exec.Command("docker", "cp", "-", container+":"+path)
+	cmd.SetStdin(tarStream)
+	return cmd.Run()
+}
+
+// CopyFrom returns a tar stream of path, read out of the container.
+func (*Docker) CopyFrom(container, path string) (io.Reader, error) {
+	pr, pw := io.Pipe()
+	cmd := exec.Command("docker", "cp", container+":"+path, "-")
+	cmd.SetStdout(pw)
+	go func() {
+		pw.CloseWithError(cmd.Run())
+	}()
+	return pr, nil
+}
+
+// Exec runs command inside container, attaching it to the current
+// process' standard streams.
+func (*Docker) Exec(container string, command ...string) error {
+	args := append([]string{"exec", container}, command...)
+	cmd := exec.Command("docker", args...)
+	cmd.SetStdin(os.Stdin)
+	cmd.SetStdout(os.Stdout)
+	cmd.SetStderr(os.Stderr)
+	return cmd.Run()
+}
@@ -0,0 +1,188 @@
+package runtime
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/exec"
+)
+
+// Podman drives containers through the `podman` CLI. Podman's CLI is
+// largely Docker-compatible, which lets it share the run arguments built by
+// dockerCompatArgs with the Docker runtime, but it talks to a per-user rootless store rather
+// than a system-wide daemon, so footloose can run without root or a Docker
+// Engine install.
+type Podman struct{}
+
+// Pull fetches image if it isn't already present locally.
+func (*Podman) Pull(image string, opts PullOptions) error {
+	if err := exec.Command("podman", "image", "exists", image).Run(); err == nil {
+		return nil
+	}
+	args := []string{"pull"}
+	if opts.Insecure {
+		args = append(args, "--tls-verify=false")
+	}
+	args = append(args, image)
+	return exec.Command("podman", args...).Run()
+}
+
+// Login authenticates against registry so that subsequent Pull calls
+// against it succeed.
+func (*Podman) Login(registry, username, password string) error {
+	cmd := exec.Command("podman", "login", "--username", username, "--password-stdin", registry)
+	cmd.SetStdin(strings.NewReader(password))
+	return cmd.Run()
+}
+
+// Tag tags the local image source under the additional reference target.
+func (*Podman) Tag(source, target string) error {
+	return exec.Command("podman", "tag", source, target).Run()
+}
+
+// Run creates and starts a new container according to spec.
+func (*Podman) Run(spec RunSpec) error {
+	runArgs, err := dockerCompatArgs(spec)
+	if err != nil {
+		return err
+	}
+	args := append([]string{"run"}, runArgs...)
+	args = append(args, spec.Image)
+	args = append(args, spec.Cmd...)
+	return exec.Command("podman", args...).Run()
+}
+
+// Start (re)starts a previously stopped container.
+func (*Podman) Start(container string) error {
+	return exec.Command("podman", "start", container).Run()
+}
+
+// Stop stops a running container without removing it.
+func (*Podman) Stop(container string) error {
+	return exec.Command("podman", "stop", container).Run()
+}
+
+// Kill sends signal to the container's init process.
+func (*Podman) Kill(container, signal string) error {
+	return exec.Command("podman", "kill", "-s", signal, container).Run()
+}
+
+// Rm removes a stopped container.
+func (*Podman) Rm(container string) error {
+	return exec.Command("podman", "rm", container).Run()
+}
+
+// podmanInspect is the subset of `podman inspect` output we care about.
+type podmanInspect struct {
+	State struct {
+		Running bool `json:"Running"`
+	} `json:"State"`
+	NetworkSettings struct {
+		IPAddress string `json:"IPAddress"`
+		Ports     map[string][]struct {
+			HostIP   string `json:"HostIp"`
+			HostPort string `json:"HostPort"`
+		} `json:"Ports"`
+	} `json:"NetworkSettings"`
+	Config struct {
+		Cmd []string `json:"Cmd"`
+	} `json:"Config"`
+	Mounts []struct {
+		Type        string `json:"Type"`
+		Source      string `json:"Source"`
+		Destination string `json:"Destination"`
+		RW          bool   `json:"RW"`
+	} `json:"Mounts"`
+}
+
+// Inspect returns details about container.
+func (*Podman) Inspect(container string) (ContainerDetails, error) {
+	var details ContainerDetails
+
+	var out bytes.Buffer
+	cmd := exec.Command("podman", "inspect", container)
+	cmd.SetStdout(&out)
+	if err := cmd.Run(); err != nil {
+		return details, err
+	}
+
+	var inspected []podmanInspect
+	if err := json.Unmarshal(out.Bytes(), &inspected); err != nil {
+		return details, err
+	}
+	if len(inspected) == 0 {
+		return details, nil
+	}
+	c := inspected[0]
+
+	details.Running = c.State.Running
+	details.IPAddress = c.NetworkSettings.IPAddress
+	details.Cmd = c.Config.Cmd
+
+	for portProto, bindings := range c.NetworkSettings.Ports {
+		if len(bindings) < 1 {
+			continue
+		}
+		containerPort := parsePort(portProto)
+		hostPort := parsePort(bindings[0].HostPort)
+		details.Ports = append(details.Ports, Port{
+			ContainerPort: containerPort,
+			HostPort:      hostPort,
+			HostIP:        bindings[0].HostIP,
+		})
+	}
+
+	for _, mount := range c.Mounts {
+		details.Mounts = append(details.Mounts, Mount{
+			Type:        mount.Type,
+			Source:      mount.Source,
+			Destination: mount.Destination,
+			ReadOnly:    !mount.RW,
+		})
+	}
+
+	return details, nil
+}
+
+func parsePort(s string) uint16 {
+	var port uint16
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			break
+		}
+		port = port*10 + uint16(r-'0')
+	}
+	return port
+}
+
+// Copy streams tarStream into the container, extracting it at path.
+func (*Podman) Copy(container string, tarStream io.Reader, path string) error {
+	cmd := exec.Command("podman", "cp", "-", container+":"+path)
+	cmd.SetStdin(tarStream)
+	return cmd.Run()
+}
+
+// CopyFrom returns a tar stream of path, read out of the container.
+func (*Podman) CopyFrom(container, path string) (io.Reader, error) {
+	pr, pw := io.Pipe()
+	cmd := exec.Command("podman", "cp", container+":"+path, "-")
+	cmd.SetStdout(pw)
+	go func() {
+		pw.CloseWithError(cmd.Run())
+	}()
+	return pr, nil
+}
+
+// Exec runs command inside container, attaching it to the current
+// process' standard streams.
+func (*Podman) Exec(container string, command ...string) error {
+	args := append([]string{"exec", container}, command...)
+	cmd := exec.Command("podman", args...)
+	cmd.SetStdin(os.Stdin)
+	cmd.SetStdout(os.Stdout)
+	cmd.SetStderr(os.Stderr)
+	return cmd.Run()
+}
@@ -0,0 +1,109 @@
+package runtime
+
+import "fmt"
+
+// seLinuxLabel maps a RunMount's SELinuxRelabel setting to the mount option
+// Docker/Podman use to relabel bind-mounts for SELinux: "z" to share the
+// content between containers, "Z" to keep it private to this one.
+func seLinuxLabel(relabel string) (string, error) {
+	switch relabel {
+	case "":
+		return "", nil
+	case "shared":
+		return "z", nil
+	case "private":
+		return "Z", nil
+	default:
+		return "", fmt.Errorf("invalid seLinuxRelabel %q, expected \"shared\" or \"private\"", relabel)
+	}
+}
+
+// dockerCompatArgs translates spec into the run flags understood by both
+// the Docker and Podman CLIs, which are Docker-compatible for every flag
+// footloose uses. It's shared by Docker.Run and Podman.Run rather than
+// duplicated between them.
+func dockerCompatArgs(spec RunSpec) ([]string, error) {
+	args := []string{
+		"-it", "-d",
+		"--name", spec.Name,
+		"--hostname", spec.Hostname,
+		"--tmpfs", "/run",
+		"--tmpfs", "/run/lock",
+		"--tmpfs", "/tmp",
+		"-v", "/sys/fs/cgroup:/sys/fs/cgroup:ro",
+	}
+	for k, v := range spec.Labels {
+		args = append(args, "--label", k+"="+v)
+	}
+
+	for _, mount := range spec.Mounts {
+		label, err := seLinuxLabel(mount.SELinuxRelabel)
+		if err != nil {
+			return nil, err
+		}
+		if label != "" && mount.Type != "bind" {
+			return nil, fmt.Errorf("destination %s: seLinuxRelabel is only meaningful for \"bind\" volumes, not %q", mount.Destination, mount.Type)
+		}
+
+		if label != "" {
+			// --mount has no equivalent of the legacy -v option's :z/:Z
+			// suffix, so relabelled bind-mounts fall back to -v.
+			opts := label
+			if mount.ReadOnly {
+				opts += ",ro"
+			}
+			args = append(args, "-v", fmt.Sprintf("%s:%s:%s", mount.Source, mount.Destination, opts))
+			continue
+		}
+
+		m := fmt.Sprintf("type=%s", mount.Type)
+		if mount.Source != "" {
+			m += fmt.Sprintf(",src=%s", mount.Source)
+		}
+		m += fmt.Sprintf(",dst=%s", mount.Destination)
+		if mount.ReadOnly {
+			m += ",readonly"
+		}
+		args = append(args, "--mount", m)
+	}
+
+	for _, p := range spec.Ports {
+		publish := ""
+		if p.Address != "" {
+			publish += p.Address + ":"
+		}
+		if p.HostPort != 0 {
+			publish += fmt.Sprintf("%d:", p.HostPort)
+		}
+		publish += fmt.Sprintf("%d", p.ContainerPort)
+		if p.Protocol != "" {
+			publish += "/" + p.Protocol
+		}
+		args = append(args, "-p", publish)
+	}
+
+	if spec.Privileged {
+		args = append(args, "--privileged")
+	}
+
+	if spec.ReadOnly {
+		// The initial provisioning (initScript, authorized_keys) still
+		// needs to write under /root, so give it a tmpfs rather than
+		// relying on the (now read-only) image rootfs.
+		args = append(args, "--read-only", "--tmpfs", "/root")
+	}
+
+	if spec.PidNamespace != "" {
+		args = append(args, "--pid", spec.PidNamespace)
+	}
+
+	if spec.IpcNamespace != "" {
+		args = append(args, "--ipc", spec.IpcNamespace)
+	}
+
+	if spec.NetworkNamespace != "" {
+		args = append(args, "--network", spec.NetworkNamespace)
+	}
+
+	return args, nil
+}
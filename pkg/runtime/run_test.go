@@ -0,0 +1,88 @@
+package runtime
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDockerCompatArgsSELinuxRelabel(t *testing.T) {
+	tests := []struct {
+		name    string
+		mount   RunMount
+		wantErr bool
+		want    string // substring expected in the generated args
+	}{
+		{
+			name:  "no relabel uses --mount",
+			mount: RunMount{Type: "bind", Source: "/src", Destination: "/dst"},
+			want:  "type=bind,src=/src,dst=/dst",
+		},
+		{
+			name:  "shared relabel falls back to -v with :z",
+			mount: RunMount{Type: "bind", Source: "/src", Destination: "/dst", SELinuxRelabel: "shared"},
+			want:  "/src:/dst:z",
+		},
+		{
+			name:  "private relabel falls back to -v with :Z",
+			mount: RunMount{Type: "bind", Source: "/src", Destination: "/dst", SELinuxRelabel: "private"},
+			want:  "/src:/dst:Z",
+		},
+		{
+			name:  "read-only private relabel appends ro",
+			mount: RunMount{Type: "bind", Source: "/src", Destination: "/dst", SELinuxRelabel: "private", ReadOnly: true},
+			want:  "/src:/dst:Z,ro",
+		},
+		{
+			name:    "relabel on a non-bind volume is rejected",
+			mount:   RunMount{Type: "volume", Source: "data", Destination: "/dst", SELinuxRelabel: "shared"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown relabel value is rejected",
+			mount:   RunMount{Type: "bind", Source: "/src", Destination: "/dst", SELinuxRelabel: "bogus"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args, err := dockerCompatArgs(RunSpec{
+				Name:     "machine",
+				Hostname: "machine",
+				Mounts:   []RunMount{tt.mount},
+			})
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("dockerCompatArgs() error = %v", err)
+			}
+			if !strings.Contains(strings.Join(args, " "), tt.want) {
+				t.Fatalf("args = %q, want to contain %q", args, tt.want)
+			}
+		})
+	}
+}
+
+func TestDockerCompatArgsNamespacesAndReadOnly(t *testing.T) {
+	args, err := dockerCompatArgs(RunSpec{
+		Name:             "machine",
+		Hostname:         "machine",
+		ReadOnly:         true,
+		PidNamespace:     "host",
+		IpcNamespace:     "host",
+		NetworkNamespace: "container:other",
+	})
+	if err != nil {
+		t.Fatalf("dockerCompatArgs() error = %v", err)
+	}
+	joined := strings.Join(args, " ")
+	for _, want := range []string{"--read-only", "--tmpfs /root", "--pid host", "--ipc host", "--network container:other"} {
+		if !strings.Contains(joined, want) {
+			t.Fatalf("args = %q, want to contain %q", joined, want)
+		}
+	}
+}
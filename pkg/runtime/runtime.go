@@ -0,0 +1,146 @@
+// Package runtime abstracts the container backend footloose uses to create
+// and manage machines, so that the cluster package doesn't have to shell out
+// to a specific container engine directly.
+package runtime
+
+import (
+	"fmt"
+	"io"
+)
+
+// Port is a single port published from a container to the host.
+type Port struct {
+	ContainerPort uint16
+	HostPort      uint16
+	HostIP        string
+}
+
+// Mount is a bind-mount, tmpfs or named volume attached to a container.
+type Mount struct {
+	Type        string
+	Source      string
+	Destination string
+	ReadOnly    bool
+}
+
+// ContainerDetails is the backend-agnostic subset of container metadata
+// footloose needs to reconcile a Machine against reality.
+type ContainerDetails struct {
+	Running   bool
+	IPAddress string
+	Cmd       []string
+	Ports     []Port
+	Mounts    []Mount
+}
+
+// PullOptions controls how Pull fetches an image.
+type PullOptions struct {
+	// Insecure allows contacting the registry over plain HTTP, or over
+	// HTTPS without verifying its certificate.
+	Insecure bool
+}
+
+// RunMount is a filesystem mount attached to a container.
+type RunMount struct {
+	// Type is "bind", "volume" or "tmpfs".
+	Type        string
+	Source      string
+	Destination string
+	ReadOnly    bool
+
+	// SELinuxRelabel is "", "shared" or "private". See
+	// config.Volume.SELinuxRelabel.
+	SELinuxRelabel string
+}
+
+// RunPort is a port published from the host to a container.
+type RunPort struct {
+	Address       string
+	HostPort      uint16
+	ContainerPort uint16
+	Protocol      string
+}
+
+// RunSpec is the backend-neutral description of a machine container built
+// by the cluster package. Each Runtime implementation translates it into
+// the flags or API calls its own engine understands.
+type RunSpec struct {
+	Image    string
+	Name     string
+	Hostname string
+	Cmd      []string
+	Labels   map[string]string
+	Mounts   []RunMount
+	Ports    []RunPort
+
+	Privileged       bool
+	ReadOnly         bool
+	PidNamespace     string
+	IpcNamespace     string
+	NetworkNamespace string
+}
+
+// Runtime is implemented by every container backend footloose can drive to
+// create, run and tear down machines. Callers build a backend-neutral
+// RunSpec; translating it into the flags a specific engine understands is
+// each implementation's job.
+type Runtime interface {
+	// Pull fetches image if it isn't already present locally.
+	Pull(image string, opts PullOptions) error
+
+	// Login authenticates against registry so that subsequent Pull calls
+	// against it succeed.
+	Login(registry, username, password string) error
+
+	// Tag tags the local image source under the additional reference
+	// target, so that target resolves locally without a further pull.
+	Tag(source, target string) error
+
+	// Run creates and starts a new container according to spec.
+	Run(spec RunSpec) error
+
+	// Start (re)starts a previously stopped container.
+	Start(container string) error
+
+	// Stop stops a running container without removing it.
+	Stop(container string) error
+
+	// Kill sends signal to the container's init process.
+	Kill(container, signal string) error
+
+	// Rm removes a stopped container.
+	Rm(container string) error
+
+	// Inspect returns details about container.
+	Inspect(container string) (ContainerDetails, error)
+
+	// Copy streams tarStream into the container, extracting it at path.
+	Copy(container string, tarStream io.Reader, path string) error
+
+	// CopyFrom returns a tar stream of path, read out of the container.
+	CopyFrom(container, path string) (io.Reader, error)
+
+	// Exec runs command inside container, attaching it to the current
+	// process' standard streams.
+	Exec(container string, command ...string) error
+}
+
+// Default is the name of the runtime used when a cluster's configuration
+// doesn't request one explicitly.
+const Default = "docker"
+
+// New returns the Runtime implementation registered under name. An empty
+// name selects Default.
+func New(name string) (Runtime, error) {
+	if name == "" {
+		name = Default
+	}
+	switch name {
+	case "docker":
+		return &Docker{}, nil
+	case "podman":
+		return &Podman{}, nil
+	default:
+		return nil, fmt.Errorf("unknown runtime %q, expected \"docker\" or \"podman\"", name)
+	}
+}